@@ -0,0 +1,126 @@
+package web
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// SubjectExtractor resolves the authenticated subject for an incoming RPC.
+// Implementations should fail with a gRPC status error (via status.New) so
+// callers that can't be identified get a proper error code rather than a
+// plain Go error.
+type SubjectExtractor interface {
+	Extract(ctx context.Context) (string, error)
+}
+
+// errNoExtractor is returned by ExtractorChain.Extract when the chain is
+// empty, so there was never an extractor to fail in the first place.
+var errNoExtractor = status.New(codes.Unauthenticated, "no subject extractor configured").Err()
+
+// ExtractorChain tries each SubjectExtractor in order and returns the first
+// subject extracted successfully. If every extractor fails, it returns the
+// error from the last one tried.
+type ExtractorChain []SubjectExtractor
+
+func (c ExtractorChain) Extract(ctx context.Context) (string, error) {
+	err := errNoExtractor
+	for _, extractor := range c {
+		var subject string
+		subject, err = extractor.Extract(ctx)
+		if err == nil {
+			return subject, nil
+		}
+	}
+	return "", err
+}
+
+// MTLSSubjectExtractor extracts the subject from the client's verified TLS
+// certificate chain. This is the extractor ledger used before pluggable
+// authentication was added, kept as the default for backwards compat.
+type MTLSSubjectExtractor struct{}
+
+func (MTLSSubjectExtractor) Extract(ctx context.Context) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", status.New(codes.Unknown, "couldn't find peer info").Err()
+	}
+
+	if p.AuthInfo == nil {
+		return "", status.New(codes.Unauthenticated, "no transport security used").Err()
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return "", status.New(codes.Unauthenticated, "no verified client certificate").Err()
+	}
+
+	return tlsInfo.State.VerifiedChains[0][0].Subject.CommonName, nil
+}
+
+// JWTSubjectExtractor extracts the subject from a bearer token delivered via
+// the standard `authorization: bearer <token>` gRPC metadata header. Keyfunc
+// is handed straight to jwt.Parse (a jwks.Keyfunc works here), and Claim
+// picks which claim is mapped to the subject, defaulting to "sub".
+type JWTSubjectExtractor struct {
+	Keyfunc jwt.Keyfunc
+	Claim   string
+}
+
+func (e JWTSubjectExtractor) Extract(ctx context.Context) (string, error) {
+	if e.Keyfunc == nil {
+		return "", status.New(codes.Unauthenticated, "JWTSubjectExtractor: no Keyfunc configured").Err()
+	}
+
+	token, err := grpc_auth.AuthFromMD(ctx, "bearer")
+	if err != nil {
+		return "", err
+	}
+
+	claim := e.Claim
+	if claim == "" {
+		claim = "sub"
+	}
+
+	parsed, err := jwt.Parse(token, e.Keyfunc)
+	if err != nil || !parsed.Valid {
+		return "", status.New(codes.Unauthenticated, fmt.Sprintf("invalid bearer token: %v", err)).Err()
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", status.New(codes.Unauthenticated, "unsupported token claims").Err()
+	}
+
+	subject, ok := claims[claim].(string)
+	if !ok {
+		return "", status.New(codes.Unauthenticated, fmt.Sprintf("token missing %q claim", claim)).Err()
+	}
+
+	return subject, nil
+}
+
+// StaticAPIKeyExtractor maps static API keys, delivered the same way as a
+// JWT via the `authorization: bearer <key>` metadata header, to subjects.
+// It's meant for tests and local development, not production use.
+type StaticAPIKeyExtractor map[string]string
+
+func (e StaticAPIKeyExtractor) Extract(ctx context.Context) (string, error) {
+	key, err := grpc_auth.AuthFromMD(ctx, "bearer")
+	if err != nil {
+		return "", err
+	}
+
+	subject, ok := e[key]
+	if !ok {
+		return "", status.New(codes.Unauthenticated, "unknown api key").Err()
+	}
+
+	return subject, nil
+}
@@ -0,0 +1,72 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type stubExtractor struct {
+	subject string
+	err     error
+}
+
+func (s stubExtractor) Extract(ctx context.Context) (string, error) {
+	return s.subject, s.err
+}
+
+func TestExtractorChain_Extract(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	tests := map[string]struct {
+		chain       ExtractorChain
+		wantSubject string
+		wantErr     error
+	}{
+		"empty chain fails": {
+			chain:   ExtractorChain{},
+			wantErr: errNoExtractor,
+		},
+		"first extractor wins": {
+			chain: ExtractorChain{
+				stubExtractor{subject: "alice"},
+				stubExtractor{subject: "bob"},
+			},
+			wantSubject: "alice",
+		},
+		"falls through failing extractors in order": {
+			chain: ExtractorChain{
+				stubExtractor{err: errBoom},
+				stubExtractor{subject: "bob"},
+			},
+			wantSubject: "bob",
+		},
+		"returns the last error when every extractor fails": {
+			chain: ExtractorChain{
+				stubExtractor{err: errBoom},
+				stubExtractor{err: errNoExtractor},
+			},
+			wantErr: errNoExtractor,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			subject, err := tt.chain.Extract(context.Background())
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got err %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if subject != tt.wantSubject {
+				t.Fatalf("got subject %q, want %q", subject, tt.wantSubject)
+			}
+		})
+	}
+}
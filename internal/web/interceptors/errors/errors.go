@@ -0,0 +1,110 @@
+// Package errors converts between ledger's domain errors (e.g.
+// api.ErrOffsetOutOfRange) and *status.Status values carrying structured
+// gRPC error details, in both directions: server interceptors map domain
+// errors to statuses on the way out, client interceptors map them back to
+// typed Go errors on the way in.
+package errors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Mapper converts a known domain error into a *status.Status carrying
+// structured detail. Mappers return nil when they don't recognize err, so
+// the next registered mapper gets a turn.
+type Mapper func(err error) *status.Status
+
+var mappers []Mapper
+
+// RegisterMapper adds m to the chain of error mappers the server
+// interceptor tries, in registration order. Packages outside web/ can use
+// this to extend the domain-error-to-status mapping without an import
+// cycle back into web.
+func RegisterMapper(m Mapper) {
+	mappers = append(mappers, m)
+}
+
+func toStatus(err error) *status.Status {
+	if st, ok := status.FromError(err); ok && st.Code() != codes.Unknown {
+		return st
+	}
+	for _, m := range mappers {
+		if st := m(err); st != nil {
+			return st
+		}
+	}
+	return status.New(codes.Unknown, err.Error())
+}
+
+// UnaryServerInterceptor converts known domain errors returned by the
+// handler into *status.Status errors with structured details attached.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, toStatus(err).Err()
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of
+// UnaryServerInterceptor.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		if err != nil {
+			return toStatus(err).Err()
+		}
+		return nil
+	}
+}
+
+// UnaryClientInterceptor reverses the server-side mapping so callers get
+// typed Go errors back (e.g. OffsetOutOfRangeError) instead of a bare
+// *status.Status.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return fromStatus(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor is the streaming counterpart of
+// UnaryClientInterceptor.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		s, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return s, fromStatus(err)
+		}
+		return &errorTranslatingStream{ClientStream: s}, nil
+	}
+}
+
+type errorTranslatingStream struct {
+	grpc.ClientStream
+}
+
+func (s *errorTranslatingStream) RecvMsg(m interface{}) error {
+	return fromStatus(s.ClientStream.RecvMsg(m))
+}
+
+func fromStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	for _, d := range st.Details() {
+		if typed := domainErrorFromDetail(d); typed != nil {
+			return typed
+		}
+	}
+	return err
+}
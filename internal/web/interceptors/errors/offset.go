@@ -0,0 +1,74 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	api "ledger/api/v1"
+)
+
+const offsetDetailReason = "OFFSET_OUT_OF_RANGE"
+
+func init() {
+	RegisterMapper(mapOffsetOutOfRange)
+}
+
+func mapOffsetOutOfRange(err error) *status.Status {
+	var offsetErr api.ErrOffsetOutOfRange
+	if !errors.As(err, &offsetErr) {
+		return nil
+	}
+
+	st := status.New(codes.OutOfRange, fmt.Sprintf("offset out of range: %d", offsetErr.Offset))
+	withDetails, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: offsetDetailReason,
+		Metadata: map[string]string{
+			"offset": strconv.FormatUint(offsetErr.Offset, 10),
+		},
+	})
+	if detailErr != nil {
+		return st
+	}
+	return withDetails
+}
+
+// OffsetOutOfRangeError is the typed client-side error reconstructed from a
+// server's OFFSET_OUT_OF_RANGE status detail.
+type OffsetOutOfRangeError struct {
+	Offset uint64
+}
+
+func (e OffsetOutOfRangeError) Error() string {
+	return fmt.Sprintf("offset out of range: %d", e.Offset)
+}
+
+// IsOffsetOutOfRange reports whether err is an out-of-range offset, whether
+// it's still the raw api.ErrOffsetOutOfRange (not yet sent over gRPC) or the
+// OffsetOutOfRangeError reconstructed on the client side.
+func IsOffsetOutOfRange(err error) bool {
+	var offsetErr api.ErrOffsetOutOfRange
+	if errors.As(err, &offsetErr) {
+		return true
+	}
+	var mappedErr OffsetOutOfRangeError
+	return errors.As(err, &mappedErr)
+}
+
+func domainErrorFromDetail(detail interface{}) error {
+	info, ok := detail.(*errdetails.ErrorInfo)
+	if !ok || info.Reason != offsetDetailReason {
+		return nil
+	}
+
+	offset, err := strconv.ParseUint(info.Metadata["offset"], 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	return OffsetOutOfRangeError{Offset: offset}
+}
@@ -0,0 +1,85 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+
+	api "ledger/api/v1"
+)
+
+func TestMapOffsetOutOfRange_RoundTrip(t *testing.T) {
+	tests := map[string]struct {
+		err error
+	}{
+		"bare domain error": {
+			err: api.ErrOffsetOutOfRange{Offset: 42},
+		},
+		"wrapped domain error": {
+			err: fmt.Errorf("append: %w", api.ErrOffsetOutOfRange{Offset: 42}),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			st := mapOffsetOutOfRange(tt.err)
+			if st == nil {
+				t.Fatal("mapOffsetOutOfRange returned nil, want a status")
+			}
+			if st.Code() != codes.OutOfRange {
+				t.Fatalf("got code %v, want %v", st.Code(), codes.OutOfRange)
+			}
+
+			details := st.Details()
+			if len(details) != 1 {
+				t.Fatalf("got %d details, want 1", len(details))
+			}
+
+			got := domainErrorFromDetail(details[0])
+			want := OffsetOutOfRangeError{Offset: 42}
+			if got != want {
+				t.Fatalf("domainErrorFromDetail() = %#v, want %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestMapOffsetOutOfRange_UnknownError(t *testing.T) {
+	if st := mapOffsetOutOfRange(fmt.Errorf("some other failure")); st != nil {
+		t.Fatalf("mapOffsetOutOfRange() = %v, want nil", st)
+	}
+}
+
+func TestFromStatus_ReconstructsTypedError(t *testing.T) {
+	err := toStatus(api.ErrOffsetOutOfRange{Offset: 7}).Err()
+
+	got := fromStatus(err)
+	want := OffsetOutOfRangeError{Offset: 7}
+	if got != want {
+		t.Fatalf("fromStatus() = %#v, want %#v", got, want)
+	}
+}
+
+func TestIsOffsetOutOfRange(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"bare domain error":    {err: api.ErrOffsetOutOfRange{Offset: 1}, want: true},
+		"wrapped domain error": {err: fmt.Errorf("read: %w", api.ErrOffsetOutOfRange{Offset: 1}), want: true},
+		"reconstructed client error": {
+			err:  OffsetOutOfRangeError{Offset: 1},
+			want: true,
+		},
+		"unrelated error": {err: fmt.Errorf("boom"), want: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := IsOffsetOutOfRange(tt.err); got != tt.want {
+				t.Fatalf("IsOffsetOutOfRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
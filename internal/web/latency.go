@@ -0,0 +1,116 @@
+package web
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// LatencyReporter receives a latency observation for every completed RPC:
+// its full method name, the resulting status code, and how long it took
+// (the raw duration, not a bucket index). Implementations can export these as Prometheus
+// counters/histograms; it's safe to leave Config.LatencyReporter nil, in
+// which case only the slow-request log below still runs.
+type LatencyReporter interface {
+	Observe(method string, code codes.Code, d time.Duration)
+}
+
+// defaultLatencyBuckets are the exponential boundaries used when
+// Config.LatencyBuckets is left unset.
+var defaultLatencyBuckets = []time.Duration{
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	10 * time.Second,
+}
+
+func (config *Config) latencyBuckets() []time.Duration {
+	if len(config.LatencyBuckets) > 0 {
+		return config.LatencyBuckets
+	}
+	return defaultLatencyBuckets
+}
+
+// bucketIndex returns the index of the smallest boundary in sorted
+// boundaries that d doesn't exceed, or len(boundaries) if d exceeds them
+// all (the top bucket).
+func bucketIndex(boundaries []time.Duration, d time.Duration) int {
+	return sort.Search(len(boundaries), func(i int) bool { return d <= boundaries[i] })
+}
+
+func (config *Config) observeLatency(logger *zap.Logger, method string, code codes.Code, d time.Duration, extra ...zap.Field) {
+	if config.LatencyReporter != nil {
+		config.LatencyReporter.Observe(method, code, d)
+	}
+
+	buckets := config.latencyBuckets()
+	slow := bucketIndex(buckets, d) == len(buckets)
+	if config.SlowRequestThreshold > 0 {
+		slow = d >= config.SlowRequestThreshold
+	}
+
+	if slow {
+		fields := append([]zap.Field{
+			zap.String("grpc.method", method),
+			zap.String("grpc.code", code.String()),
+			zap.Duration("grpc.time", d),
+		}, extra...)
+		logger.Warn("slow request", fields...)
+	}
+}
+
+// UnaryServerInterceptor buckets the RPC's duration, reports it to
+// config.LatencyReporter (if set), and emits a "slow request" log line via
+// logger whenever the duration reaches config.SlowRequestThreshold, or, when
+// no threshold is set, whenever it lands in the top latency bucket.
+func (config *Config) UnaryServerInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		config.observeLatency(logger, info.FullMethod, status.Code(err), time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of
+// UnaryServerInterceptor. It also counts RecvMsg and SendMsg calls over the
+// stream's lifetime, failed calls included, and includes those counts in the
+// slow-request log.
+func (config *Config) StreamServerInterceptor(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		wrapped := &countingServerStream{ServerStream: ss}
+		err := handler(srv, wrapped)
+		config.observeLatency(logger, info.FullMethod, status.Code(err), time.Since(start),
+			zap.Int("grpc.recv_count", wrapped.recvCount),
+			zap.Int("grpc.send_count", wrapped.sendCount),
+		)
+		return err
+	}
+}
+
+// countingServerStream wraps grpc.ServerStream to count RecvMsg/SendMsg
+// calls for inclusion in the slow-request log.
+type countingServerStream struct {
+	grpc.ServerStream
+	recvCount int
+	sendCount int
+}
+
+func (s *countingServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	s.recvCount++
+	return err
+}
+
+func (s *countingServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	s.sendCount++
+	return err
+}
@@ -0,0 +1,35 @@
+package web
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketIndex(t *testing.T) {
+	boundaries := []time.Duration{
+		time.Millisecond,
+		10 * time.Millisecond,
+		100 * time.Millisecond,
+	}
+
+	tests := map[string]struct {
+		d    time.Duration
+		want int
+	}{
+		"below first boundary":       {d: time.Microsecond, want: 0},
+		"exactly on first boundary":  {d: time.Millisecond, want: 0},
+		"just above first boundary":  {d: time.Millisecond + 1, want: 1},
+		"exactly on second boundary": {d: 10 * time.Millisecond, want: 1},
+		"exactly on last boundary":   {d: 100 * time.Millisecond, want: 2},
+		"above all boundaries":       {d: time.Second, want: len(boundaries)},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := bucketIndex(boundaries, tt.d)
+			if got != tt.want {
+				t.Fatalf("bucketIndex(%v) = %d, want %d", tt.d, got, tt.want)
+			}
+		})
+	}
+}
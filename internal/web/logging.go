@@ -0,0 +1,154 @@
+package web
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	api "ledger/api/v1"
+)
+
+// CodeToLevel maps a gRPC status code to the zap level a completed-RPC log
+// line is emitted at.
+type CodeToLevel func(code codes.Code) zapcore.Level
+
+// LoggerConfig configures the ctxzap-based request logging interceptors.
+type LoggerConfig struct {
+	// Logger is the base logger request-scoped fields are attached to.
+	// Defaults to Config.Logger.
+	Logger *zap.Logger
+
+	// CodeToLevel picks the zap level for the completed-RPC log line.
+	// Defaults to DefaultCodeToLevel.
+	CodeToLevel CodeToLevel
+
+	// LogPayloads additionally logs the request message at debug level.
+	// Only unary RPCs are covered; streamed messages are not logged.
+	// Expensive; leave off outside of troubleshooting.
+	LogPayloads bool
+}
+
+// DefaultCodeToLevel logs OK and ordinary client errors at info, a handful
+// of expected-but-notable codes at warn, and everything else at error.
+func DefaultCodeToLevel(code codes.Code) zapcore.Level {
+	switch code {
+	case codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound,
+		codes.AlreadyExists, codes.Unauthenticated:
+		return zapcore.InfoLevel
+	case codes.DeadlineExceeded, codes.PermissionDenied, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange, codes.Unavailable:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+func (c *LoggerConfig) codeToLevel(code codes.Code) zapcore.Level {
+	if c.CodeToLevel != nil {
+		return c.CodeToLevel(code)
+	}
+	return DefaultCodeToLevel(code)
+}
+
+// OffsetFieldExtractor is a grpc_ctxtags RequestFieldExtractorFunc that
+// pulls the commit-log offset out of ConsumeRequest so it's automatically
+// attached as a tag, and therefore to every log line, for that RPC.
+func OffsetFieldExtractor(fullMethod string, req interface{}) map[string]interface{} {
+	if r, ok := req.(*api.ConsumeRequest); ok {
+		return map[string]interface{}{"offset": r.Offset}
+	}
+	return nil
+}
+
+// requestLogger builds the per-RPC logger injected into the context: the
+// base Logger plus the standard request-scoped fields, and any
+// grpc_ctxtags already extracted from the request (e.g. via
+// OffsetFieldExtractor).
+func (c *LoggerConfig) requestLogger(ctx context.Context, method string, start time.Time) *zap.Logger {
+	fields := []zap.Field{
+		zap.String("grpc.service", serviceFromMethod(method)),
+		zap.String("grpc.method", method),
+		zap.Time("grpc.start_time", start),
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		fields = append(fields, zap.String("peer.address", p.Addr.String()))
+	}
+	if sub, ok := ctx.Value(subjectContextKey{}).(string); ok {
+		fields = append(fields, zap.String("subject", sub))
+	}
+	for k, v := range grpc_ctxtags.Extract(ctx).Values() {
+		fields = append(fields, zap.Any(k, v))
+	}
+
+	return c.Logger.With(fields...)
+}
+
+func (c *LoggerConfig) logCompletion(logger *zap.Logger, msg string, start time.Time, err error) {
+	code := status.Code(err)
+	fields := []zap.Field{
+		zap.String("grpc.code", code.String()),
+		zap.Int64("grpc.time_ms", time.Since(start).Milliseconds()),
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	if ce := logger.Check(c.codeToLevel(code), msg); ce != nil {
+		ce.Write(fields...)
+	}
+}
+
+// UnaryServerInterceptor injects a request-scoped logger into the context
+// and logs the RPC's outcome once the handler returns. Handlers can call
+// ctxzap.AddFields(ctx, ...) to append fields (e.g. offset, segment) that
+// show up on that completion log line.
+func (c *LoggerConfig) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		logger := c.requestLogger(ctx, info.FullMethod, start)
+		ctx = ctxzap.ToContext(ctx, logger)
+
+		if c.LogPayloads {
+			logger.Debug("request payload", zap.Any("grpc.request", req))
+		}
+
+		resp, err := handler(ctx, req)
+
+		c.logCompletion(ctxzap.Extract(ctx), "finished unary call", start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of
+// UnaryServerInterceptor.
+func (c *LoggerConfig) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		logger := c.requestLogger(ss.Context(), info.FullMethod, start)
+
+		wrapped := grpc_middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = ctxzap.ToContext(wrapped.WrappedContext, logger)
+
+		err := handler(srv, wrapped)
+
+		c.logCompletion(ctxzap.Extract(wrapped.WrappedContext), "finished streaming call", start, err)
+		return err
+	}
+}
+
+func serviceFromMethod(fullMethod string) string {
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return strings.TrimPrefix(fullMethod[:i], "/")
+	}
+	return fullMethod
+}
@@ -0,0 +1,88 @@
+package web
+
+import (
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// ServerParams configures connection-level hardening for NewGRPCServer:
+// keepalive pings, idle/age limits, and message-size caps. Zero values are
+// left for gRPC's own defaults. ConsumeStream's
+// long-lived loop is the main motivator: without PermitWithoutStream and a
+// keepalive ping, NAT/LB middleboxes silently drop the stream and clients
+// block forever waiting on the next Send.
+type ServerParams struct {
+	// KeepaliveTime is how often the server pings an idle connection to
+	// check it's still alive.
+	KeepaliveTime time.Duration
+	// KeepaliveTimeout is how long the server waits for a ping ack
+	// before considering the connection dead.
+	KeepaliveTimeout time.Duration
+	// KeepaliveMinTime is the minimum time a client is allowed to wait
+	// between pings; clients pinging faster get GoAway'd.
+	KeepaliveMinTime time.Duration
+	// KeepalivePermitWithoutStream allows keepalive pings even when
+	// there's no active RPC, so long-lived streams like ConsumeStream
+	// keep the connection alive between records.
+	KeepalivePermitWithoutStream bool
+
+	// MaxConnectionIdle is how long a connection may sit idle before the
+	// server sends a GoAway.
+	MaxConnectionIdle time.Duration
+	// MaxConnectionAge is the max age of a connection before the server
+	// sends a GoAway, regardless of activity.
+	MaxConnectionAge time.Duration
+	// MaxConnectionAgeGrace is the grace period after MaxConnectionAge
+	// before the server forcibly closes the connection.
+	MaxConnectionAgeGrace time.Duration
+
+	// MaxRecvMsgSize caps the size, in bytes, of a message the server
+	// will accept.
+	MaxRecvMsgSize int
+	// MaxSendMsgSize caps the size, in bytes, of a message the server
+	// will send.
+	MaxSendMsgSize int
+
+	// ConnectionTimeout bounds how long the server waits for a new
+	// connection's handshake (TCP accept through HTTP/2 settings) to
+	// complete.
+	ConnectionTimeout time.Duration
+}
+
+// serverOptions translates the non-zero fields of p into grpc.ServerOption
+// values, leaving gRPC's own defaults in place for anything left unset.
+func (p ServerParams) serverOptions() []grpc.ServerOption {
+	var opts []grpc.ServerOption
+
+	if p.KeepaliveTime > 0 || p.KeepaliveTimeout > 0 || p.MaxConnectionIdle > 0 ||
+		p.MaxConnectionAge > 0 || p.MaxConnectionAgeGrace > 0 {
+		opts = append(opts, grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle:     p.MaxConnectionIdle,
+			MaxConnectionAge:      p.MaxConnectionAge,
+			MaxConnectionAgeGrace: p.MaxConnectionAgeGrace,
+			Time:                  p.KeepaliveTime,
+			Timeout:               p.KeepaliveTimeout,
+		}))
+	}
+
+	if p.KeepaliveMinTime > 0 || p.KeepalivePermitWithoutStream {
+		opts = append(opts, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             p.KeepaliveMinTime,
+			PermitWithoutStream: p.KeepalivePermitWithoutStream,
+		}))
+	}
+
+	if p.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(p.MaxRecvMsgSize))
+	}
+	if p.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(p.MaxSendMsgSize))
+	}
+	if p.ConnectionTimeout > 0 {
+		opts = append(opts, grpc.ConnectionTimeout(p.ConnectionTimeout))
+	}
+
+	return opts
+}
@@ -2,16 +2,17 @@ package web
 
 import (
 	"context"
+	"time"
 
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/peer"
-	"google.golang.org/grpc/status"
 
 	api "ledger/api/v1"
+	weberrors "ledger/internal/web/interceptors/errors"
 )
 
 // ACL policy keywords
@@ -27,6 +28,39 @@ type Config struct {
 	CommitLog    CommitLog
 	Authorizer   Authorizer
 	ServerGetter ServerGetter
+
+	// SubjectExtractor resolves the authenticated subject passed to
+	// Authorizer.Authorize. Defaults to MTLSSubjectExtractor for
+	// backwards compat with cert-only deployments.
+	SubjectExtractor SubjectExtractor
+
+	// Logger receives the slow-request log line emitted by the latency
+	// interceptor. Defaults to a no-op logger.
+	Logger *zap.Logger
+
+	// LoggerConfig configures the per-RPC structured logging
+	// interceptor. Defaults to using Logger with DefaultCodeToLevel and
+	// payload logging off.
+	LoggerConfig LoggerConfig
+
+	// LatencyReporter is notified of every RPC's duration, for
+	// exporting to a metrics backend. Left nil, only slow-request
+	// logging runs.
+	LatencyReporter LatencyReporter
+
+	// LatencyBuckets are the sorted, exponential latency boundaries RPCs
+	// are bucketed into. Defaults to
+	// [1ms, 10ms, 100ms, 1s, 10s].
+	LatencyBuckets []time.Duration
+
+	// SlowRequestThreshold is the duration at or above which an RPC is
+	// logged as a slow request. Defaults to 0, in which case an RPC is
+	// logged as slow when it lands in LatencyBuckets' top bucket.
+	SlowRequestThreshold time.Duration
+
+	// ServerParams configures keepalive, connection age/idle limits, and
+	// message-size caps. Zero fields fall back to gRPC's own defaults.
+	ServerParams ServerParams
 }
 
 type CommitLog interface {
@@ -47,12 +81,48 @@ type grpcServer struct {
 }
 
 func NewGRPCServer(config *Config, opts ...grpc.ServerOption) (*grpc.Server, error) {
+	opts = append(config.ServerParams.serverOptions(), opts...)
+
+	if config.SubjectExtractor == nil {
+		config.SubjectExtractor = MTLSSubjectExtractor{}
+	}
+	if config.Logger == nil {
+		config.Logger = zap.NewNop()
+	}
+	if config.LoggerConfig.Logger == nil {
+		config.LoggerConfig.Logger = config.Logger
+	}
+
+	// identify (when an Authorizer is configured) must run ahead of
+	// LoggerConfig so the subject it writes to the context is visible
+	// by the time LoggerConfig builds the per-RPC logger.
+	unary := []grpc.UnaryServerInterceptor{
+		grpc_ctxtags.UnaryServerInterceptor(grpc_ctxtags.WithFieldExtractorForInitialReq(OffsetFieldExtractor)),
+	}
+	stream := []grpc.StreamServerInterceptor{
+		grpc_ctxtags.StreamServerInterceptor(grpc_ctxtags.WithFieldExtractorForInitialReq(OffsetFieldExtractor)),
+	}
+
 	if config.Authorizer != nil {
-		opts = append(opts,
-			grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(grpc_auth.StreamServerInterceptor(identify))),
-			grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(grpc_auth.UnaryServerInterceptor(identify))),
-		)
+		unary = append(unary, grpc_auth.UnaryServerInterceptor(config.identify))
+		stream = append(stream, grpc_auth.StreamServerInterceptor(config.identify))
 	}
+
+	unary = append(unary,
+		config.LoggerConfig.UnaryServerInterceptor(),
+		config.UnaryServerInterceptor(config.Logger),
+		weberrors.UnaryServerInterceptor(),
+	)
+	stream = append(stream,
+		config.LoggerConfig.StreamServerInterceptor(),
+		config.StreamServerInterceptor(config.Logger),
+		weberrors.StreamServerInterceptor(),
+	)
+
+	opts = append(opts,
+		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(stream...)),
+		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(unary...)),
+	)
 	server := grpc.NewServer(opts...)
 
 	logServer := &grpcServer{config}
@@ -73,6 +143,7 @@ func (this *grpcServer) Produce(ctx context.Context, req *api.ProduceRequest) (*
 	if err != nil {
 		return nil, err
 	}
+	ctxzap.AddFields(ctx, zap.Uint64("offset", offset))
 
 	return &api.ProduceResponse{Offset: offset}, nil
 }
@@ -85,6 +156,8 @@ func (this *grpcServer) Consume(ctx context.Context, req *api.ConsumeRequest) (*
 		}
 	}
 
+	ctxzap.AddFields(ctx, zap.Uint64("offset", req.Offset))
+
 	record, err := this.CommitLog.Read(req.Offset)
 	if err != nil {
 		return nil, err
@@ -126,11 +199,10 @@ func (this *grpcServer) ConsumeStream(req *api.ConsumeRequest, stream api.Log_Co
 		// when there are no more logs to read, the server will wait til another record is appended
 		default:
 			res, err := this.Consume(stream.Context(), req)
-			switch err.(type) {
-			case nil:
-			case api.ErrOffsetOutOfRange:
-				continue
-			default:
+			if err != nil {
+				if weberrors.IsOffsetOutOfRange(err) {
+					continue
+				}
 				return err
 			}
 
@@ -154,23 +226,15 @@ func (s *grpcServer) GetServers(ctx context.Context, req *api.GetServersRequest)
 	return &api.GetServersResponse{Servers: servers}, nil
 }
 
-// Identify the subject to enable authorization
-// Interceptor/middleware reads subject out of the client's cert and writes it to the RPC's context
-func identify(ctx context.Context) (context.Context, error) {
-	peer, ok := peer.FromContext(ctx)
-	if !ok {
-		return ctx, status.New(codes.Unknown, "couldn't find peer info").Err()
-	}
-
-	if peer.AuthInfo == nil {
-		return ctx, status.New(codes.Unauthenticated, "no transport security used").Err()
+// identify resolves the subject via config's SubjectExtractor and writes it
+// to the RPC's context to enable authorization.
+func (config *Config) identify(ctx context.Context) (context.Context, error) {
+	subject, err := config.SubjectExtractor.Extract(ctx)
+	if err != nil {
+		return ctx, err
 	}
 
-	tlsInfo := peer.AuthInfo.(credentials.TLSInfo)
-	subject := tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
-	ctx = context.WithValue(ctx, subjectContextKey{}, subject)
-
-	return ctx, nil
+	return context.WithValue(ctx, subjectContextKey{}, subject), nil
 }
 
 func subject(ctx context.Context) string {